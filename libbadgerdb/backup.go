@@ -0,0 +1,137 @@
+package main
+
+/*
+#include <stdint.h>
+*/
+import "C"
+
+import (
+	"os"
+	"syscall"
+
+	badger "github.com/dgraph-io/badger/v4"
+)
+
+func backupImpl(path, outFile string, sinceTs uint64) error {
+	db, ok := getDB(path)
+	if !ok {
+		return badger.ErrDBClosed
+	}
+
+	f, err := os.Create(outFile)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = db.Backup(f, sinceTs)
+	return err
+}
+
+// Backup writes a full (sinceTs == 0) or incremental (sinceTs > 0, per
+// badger's version counter) snapshot of the DB at path to outFile.
+//
+//export Backup
+func Backup(path *C.char, outFile *C.char, sinceTs C.uint64_t) C.int {
+	return setLastError(backupImpl(C.GoString(path), C.GoString(outFile), uint64(sinceTs)))
+}
+
+func restoreImpl(path, inFile string) error {
+	db, ok := getDB(path)
+	if !ok {
+		return badger.ErrDBClosed
+	}
+
+	f, err := os.Open(inFile)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return db.Load(f, 16)
+}
+
+// Restore loads a snapshot produced by Backup (or StreamBackup) from inFile
+// into the DB at path.
+//
+//export Restore
+func Restore(path *C.char, inFile *C.char) C.int {
+	return setLastError(restoreImpl(C.GoString(path), C.GoString(inFile)))
+}
+
+func streamBackupImpl(path string, fd int, sinceTs uint64) error {
+	db, ok := getDB(path)
+	if !ok {
+		return badger.ErrDBClosed
+	}
+
+	// The caller retains ownership of fd, so we must not close it on their
+	// behalf. os.NewFile attaches a finalizer that closes its underlying fd
+	// once the *os.File is garbage collected, and that finalizer lives on an
+	// internal field we can't reach to disable — so instead of wrapping fd
+	// itself, we dup it and wrap the copy; closing (or finalizing) our copy
+	// then never touches the caller's original descriptor.
+	dupFd, err := syscall.Dup(fd)
+	if err != nil {
+		return err
+	}
+	f := os.NewFile(uintptr(dupFd), "stream-backup")
+	if f == nil {
+		syscall.Close(dupFd)
+		return os.ErrInvalid
+	}
+	defer f.Close()
+
+	_, err = db.Backup(f, sinceTs)
+	return err
+}
+
+// StreamBackup is like Backup but writes directly to a caller-provided file
+// descriptor, so large snapshots can be piped (e.g. to a compressor or over
+// the network) without an intermediate buffer.
+//
+//export StreamBackup
+func StreamBackup(path *C.char, fd C.int, sinceTs C.uint64_t) C.int {
+	return setLastError(streamBackupImpl(C.GoString(path), int(fd), uint64(sinceTs)))
+}
+
+func runValueLogGCImpl(path string, discardRatio float64) error {
+	db, ok := getDB(path)
+	if !ok {
+		return badger.ErrDBClosed
+	}
+
+	err := db.RunValueLogGC(discardRatio)
+	if err == badger.ErrNoRewrite {
+		return nil
+	}
+	return err
+}
+
+// RunValueLogGC runs a single value-log garbage collection cycle, rewriting
+// the log if at least discardRatio of it is reclaimable. badger.ErrNoRewrite
+// is treated as a no-op success since it simply means there was nothing to
+// collect.
+//
+//export RunValueLogGC
+func RunValueLogGC(path *C.char, discardRatio C.double) C.int {
+	return setLastError(runValueLogGCImpl(C.GoString(path), float64(discardRatio)))
+}
+
+func flattenImpl(path string, workers int) error {
+	db, ok := getDB(path)
+	if !ok {
+		return badger.ErrDBClosed
+	}
+
+	return db.Flatten(workers)
+}
+
+// Flatten merges the LSM tree down to a single level using the given
+// number of concurrent workers, to reduce read amplification after heavy
+// writes.
+//
+//export Flatten
+func Flatten(path *C.char, workers C.int) C.int {
+	return setLastError(flattenImpl(C.GoString(path), int(workers)))
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestBackupRestore(t *testing.T) {
+	srcPath := openTestDB(t)
+	if err := setImpl(srcPath, []byte("backup-key"), []byte("backup-val")); err != nil {
+		t.Fatalf("setImpl failed: %v", err)
+	}
+
+	snapshot := filepath.Join(t.TempDir(), "snapshot")
+	if err := backupImpl(srcPath, snapshot, 0); err != nil {
+		t.Fatalf("backupImpl failed: %v", err)
+	}
+
+	dstPath := openTestDB(t)
+	if err := restoreImpl(dstPath, snapshot); err != nil {
+		t.Fatalf("restoreImpl failed: %v", err)
+	}
+
+	val, err := getImpl(dstPath, []byte("backup-key"))
+	if err != nil {
+		t.Fatalf("getImpl after restore failed: %v", err)
+	}
+	if string(val) != "backup-val" {
+		t.Fatalf("getImpl after restore = %q, want %q", val, "backup-val")
+	}
+}
+
+func TestStreamBackupDoesNotCloseCallerFD(t *testing.T) {
+	path := openTestDB(t)
+	if err := setImpl(path, []byte("stream-key"), []byte("stream-val")); err != nil {
+		t.Fatalf("setImpl failed: %v", err)
+	}
+
+	f, err := os.Create(filepath.Join(t.TempDir(), "stream"))
+	if err != nil {
+		t.Fatalf("os.Create failed: %v", err)
+	}
+	defer f.Close()
+
+	if err := streamBackupImpl(path, int(f.Fd()), 0); err != nil {
+		t.Fatalf("streamBackupImpl failed: %v", err)
+	}
+
+	// streamBackupImpl wraps the fd in its own *os.File internally; force
+	// that wrapper to be collected so a left-over finalizer would fire here,
+	// not at some nondeterministic point later in the caller's process.
+	runtime.GC()
+	runtime.GC()
+
+	// If streamBackupImpl had closed the caller's fd (directly, or via a
+	// finalizer on its internal os.File wrapper), this write would fail.
+	if _, err := f.WriteString("still open"); err != nil {
+		t.Fatalf("fd was closed out from under the caller: %v", err)
+	}
+}
+
+func TestRunValueLogGCAndFlatten(t *testing.T) {
+	path := openTestDB(t)
+	if err := setImpl(path, []byte("gc-key"), []byte("gc-val")); err != nil {
+		t.Fatalf("setImpl failed: %v", err)
+	}
+
+	// No garbage has accumulated yet, so this should report the "nothing to
+	// rewrite" case as success rather than an error.
+	if err := runValueLogGCImpl(path, 0.5); err != nil {
+		t.Fatalf("runValueLogGCImpl failed: %v", err)
+	}
+
+	if err := flattenImpl(path, 1); err != nil {
+		t.Fatalf("flattenImpl failed: %v", err)
+	}
+}
@@ -0,0 +1,250 @@
+package main
+
+/*
+#include <stdint.h>
+*/
+import "C"
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+	"unsafe"
+
+	badger "github.com/dgraph-io/badger/v4"
+)
+
+var (
+	batchMu     sync.RWMutex
+	batchMap    map[uint64]*badger.WriteBatch
+	nextBatchID uint64
+	batchOnce   sync.Once
+)
+
+func initBatchMap() {
+	batchOnce.Do(func() {
+		batchMap = make(map[uint64]*badger.WriteBatch)
+	})
+}
+
+func getBatch(handle uint64) (*badger.WriteBatch, bool) {
+	batchMu.RLock()
+	defer batchMu.RUnlock()
+	wb, ok := batchMap[handle]
+	return wb, ok
+}
+
+func newWriteBatchImpl(path string) (uint64, error) {
+	initBatchMap()
+
+	db, ok := getDB(path)
+	if !ok {
+		return 0, badger.ErrDBClosed
+	}
+
+	wb := db.NewWriteBatch()
+
+	handle := atomic.AddUint64(&nextBatchID, 1)
+	batchMu.Lock()
+	batchMap[handle] = wb
+	batchMu.Unlock()
+
+	return handle, nil
+}
+
+// NewWriteBatch opens a badger.WriteBatch against the DB at path for
+// high-throughput bulk writes, returning an opaque handle or 0 on failure.
+//
+//export NewWriteBatch
+func NewWriteBatch(path *C.char) C.uint64_t {
+	handle, err := newWriteBatchImpl(C.GoString(path))
+	if err != nil {
+		setLastError(err)
+		return 0
+	}
+	return C.uint64_t(handle)
+}
+
+func writeBatchSetImpl(handle uint64, key, val []byte, ttlSeconds uint64, meta byte) error {
+	wb, ok := getBatch(handle)
+	if !ok {
+		return badger.ErrDiscardedTxn
+	}
+
+	entry := badger.NewEntry(key, val).WithMeta(meta)
+	if ttlSeconds > 0 {
+		entry = entry.WithTTL(time.Duration(ttlSeconds) * time.Second)
+	}
+
+	return wb.SetEntry(entry)
+}
+
+//export WriteBatchSet
+func WriteBatchSet(handle C.uint64_t, key *C.char, keyLen C.int, val *C.char, valLen C.int, ttlSeconds C.uint64_t, meta C.uchar) C.int {
+	k := C.GoBytes(unsafe.Pointer(key), keyLen)
+	v := C.GoBytes(unsafe.Pointer(val), valLen)
+	return setLastError(writeBatchSetImpl(uint64(handle), k, v, uint64(ttlSeconds), byte(meta)))
+}
+
+func writeBatchDeleteImpl(handle uint64, key []byte) error {
+	wb, ok := getBatch(handle)
+	if !ok {
+		return badger.ErrDiscardedTxn
+	}
+	return wb.Delete(key)
+}
+
+//export WriteBatchDelete
+func WriteBatchDelete(handle C.uint64_t, key *C.char, keyLen C.int) C.int {
+	k := C.GoBytes(unsafe.Pointer(key), keyLen)
+	return setLastError(writeBatchDeleteImpl(uint64(handle), k))
+}
+
+func writeBatchFlushImpl(handle uint64) error {
+	batchMu.Lock()
+	wb, ok := batchMap[handle]
+	delete(batchMap, handle)
+	batchMu.Unlock()
+
+	if !ok {
+		return badger.ErrDiscardedTxn
+	}
+	return wb.Flush()
+}
+
+// WriteBatchFlush commits all pending writes and releases the batch handle.
+//
+//export WriteBatchFlush
+func WriteBatchFlush(handle C.uint64_t) C.int {
+	return setLastError(writeBatchFlushImpl(uint64(handle)))
+}
+
+func writeBatchCancelImpl(handle uint64) error {
+	batchMu.Lock()
+	wb, ok := batchMap[handle]
+	delete(batchMap, handle)
+	batchMu.Unlock()
+
+	if !ok {
+		return badger.ErrDiscardedTxn
+	}
+	wb.Cancel()
+	return nil
+}
+
+// WriteBatchCancel discards all pending writes and releases the batch
+// handle without committing anything.
+//
+//export WriteBatchCancel
+func WriteBatchCancel(handle C.uint64_t) C.int {
+	return setLastError(writeBatchCancelImpl(uint64(handle)))
+}
+
+func setWithTTLImpl(path string, key, val []byte, ttlSeconds uint64) error {
+	db, ok := getDB(path)
+	if !ok {
+		return badger.ErrDBClosed
+	}
+
+	entry := badger.NewEntry(key, val)
+	if ttlSeconds > 0 {
+		entry = entry.WithTTL(time.Duration(ttlSeconds) * time.Second)
+	}
+
+	return db.Update(func(txn *badger.Txn) error {
+		return txn.SetEntry(entry)
+	})
+}
+
+// SetWithTTL is like Set but expires the key after ttlSeconds.
+//
+//export SetWithTTL
+func SetWithTTL(path *C.char, key *C.char, keyLen C.int, val *C.char, valLen C.int, ttlSeconds C.uint64_t) C.int {
+	k := C.GoBytes(unsafe.Pointer(key), keyLen)
+	v := C.GoBytes(unsafe.Pointer(val), valLen)
+	return setLastError(setWithTTLImpl(C.GoString(path), k, v, uint64(ttlSeconds)))
+}
+
+func getWithVersionImpl(path string, key []byte) ([]byte, uint64, error) {
+	db, ok := getDB(path)
+	if !ok {
+		return nil, 0, badger.ErrDBClosed
+	}
+
+	var val []byte
+	var version uint64
+	err := db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(key)
+		if err != nil {
+			return err
+		}
+		version = item.Version()
+		val, err = item.ValueCopy(nil)
+		return err
+	})
+	return val, version, err
+}
+
+// GetWithVersion looks up key's current value, like Get, but also reports
+// the version it was written at.
+//
+//export GetWithVersion
+func GetWithVersion(path *C.char, key *C.char, keyLen C.int, outVal **C.char, outLen *C.int, outVersion *C.uint64_t) C.int {
+	k := C.GoBytes(unsafe.Pointer(key), keyLen)
+
+	val, version, err := getWithVersionImpl(C.GoString(path), k)
+	if err != nil {
+		return setLastError(err)
+	}
+
+	*outVal = (*C.char)(C.CBytes(val))
+	*outLen = C.int(len(val))
+	*outVersion = C.uint64_t(version)
+	return statusOK
+}
+
+func getAllVersionsImpl(path string, key []byte) (uint64, error) {
+	initTxnMaps()
+
+	db, ok := getDB(path)
+	if !ok {
+		return 0, badger.ErrDBClosed
+	}
+
+	txn := db.NewTransaction(false)
+
+	opts := badger.DefaultIteratorOptions
+	opts.AllVersions = true
+	opts.Prefix = key
+	it := txn.NewIterator(opts)
+	it.Seek(key)
+
+	txnHandle := atomic.AddUint64(&nextTxnID, 1)
+	txnMu.Lock()
+	txnMap[txnHandle] = txn
+	txnMu.Unlock()
+
+	iterHandle := atomic.AddUint64(&nextIterID, 1)
+	iterMu.Lock()
+	iterMap[iterHandle] = &badgerIterator{txn: txnHandle, it: it, ownsTxn: true, exactKey: key}
+	iterMu.Unlock()
+
+	return iterHandle, nil
+}
+
+// GetAllVersions opens a read-only iterator over every retained version of
+// key, newest first, and returns an opaque handle usable with IterValid,
+// IterNext, IterKey, IterValue, and IterClose. The handle owns its own
+// transaction, which is discarded automatically by IterClose.
+//
+//export GetAllVersions
+func GetAllVersions(path *C.char, key *C.char, keyLen C.int) C.uint64_t {
+	k := C.GoBytes(unsafe.Pointer(key), keyLen)
+
+	handle, err := getAllVersionsImpl(C.GoString(path), k)
+	if err != nil {
+		setLastError(err)
+		return 0
+	}
+	return C.uint64_t(handle)
+}
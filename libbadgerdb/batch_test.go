@@ -0,0 +1,184 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWriteBatchFlush(t *testing.T) {
+	path := openTestDB(t)
+
+	wb, err := newWriteBatchImpl(path)
+	if err != nil {
+		t.Fatalf("newWriteBatchImpl failed: %v", err)
+	}
+	if err := writeBatchSetImpl(wb, []byte("batch-key"), []byte("batch-val"), 0, 0); err != nil {
+		t.Fatalf("writeBatchSetImpl failed: %v", err)
+	}
+	if err := writeBatchFlushImpl(wb); err != nil {
+		t.Fatalf("writeBatchFlushImpl failed: %v", err)
+	}
+
+	val, err := getImpl(path, []byte("batch-key"))
+	if err != nil {
+		t.Fatalf("getImpl after flush failed: %v", err)
+	}
+	if string(val) != "batch-val" {
+		t.Fatalf("getImpl after flush = %q, want %q", val, "batch-val")
+	}
+}
+
+func TestWriteBatchCancel(t *testing.T) {
+	path := openTestDB(t)
+
+	wb, err := newWriteBatchImpl(path)
+	if err != nil {
+		t.Fatalf("newWriteBatchImpl failed: %v", err)
+	}
+	if err := writeBatchSetImpl(wb, []byte("cancelled-key"), []byte("cancelled-val"), 0, 0); err != nil {
+		t.Fatalf("writeBatchSetImpl failed: %v", err)
+	}
+	if err := writeBatchCancelImpl(wb); err != nil {
+		t.Fatalf("writeBatchCancelImpl failed: %v", err)
+	}
+
+	found, err := existsImpl(path, []byte("cancelled-key"))
+	if err != nil {
+		t.Fatalf("existsImpl failed: %v", err)
+	}
+	if found {
+		t.Fatalf("existsImpl = true for a key whose batch was cancelled")
+	}
+}
+
+func TestSetWithTTLExpires(t *testing.T) {
+	path := openTestDB(t)
+
+	if err := setWithTTLImpl(path, []byte("ttl-key"), []byte("ttl-val"), 1); err != nil {
+		t.Fatalf("setWithTTLImpl failed: %v", err)
+	}
+
+	found, err := existsImpl(path, []byte("ttl-key"))
+	if err != nil {
+		t.Fatalf("existsImpl failed: %v", err)
+	}
+	if !found {
+		t.Fatalf("existsImpl = false before expiry")
+	}
+
+	time.Sleep(2 * time.Second)
+
+	found, err = existsImpl(path, []byte("ttl-key"))
+	if err != nil {
+		t.Fatalf("existsImpl failed: %v", err)
+	}
+	if found {
+		t.Fatalf("existsImpl = true after expiry")
+	}
+}
+
+func TestSetWithTTLZeroMeansNoExpiry(t *testing.T) {
+	path := openTestDB(t)
+
+	if err := setWithTTLImpl(path, []byte("no-ttl-key"), []byte("no-ttl-val"), 0); err != nil {
+		t.Fatalf("setWithTTLImpl failed: %v", err)
+	}
+
+	val, err := getImpl(path, []byte("no-ttl-key"))
+	if err != nil {
+		t.Fatalf("getImpl failed: %v", err)
+	}
+	if string(val) != "no-ttl-val" {
+		t.Fatalf("getImpl = %q, want %q", val, "no-ttl-val")
+	}
+}
+
+func TestGetWithVersionIncrements(t *testing.T) {
+	path := openTestDB(t)
+
+	if err := setImpl(path, []byte("ver-key"), []byte("v1")); err != nil {
+		t.Fatalf("setImpl v1 failed: %v", err)
+	}
+	_, version1, err := getWithVersionImpl(path, []byte("ver-key"))
+	if err != nil {
+		t.Fatalf("getWithVersionImpl v1 failed: %v", err)
+	}
+
+	if err := setImpl(path, []byte("ver-key"), []byte("v2")); err != nil {
+		t.Fatalf("setImpl v2 failed: %v", err)
+	}
+	_, version2, err := getWithVersionImpl(path, []byte("ver-key"))
+	if err != nil {
+		t.Fatalf("getWithVersionImpl v2 failed: %v", err)
+	}
+
+	if version2 <= version1 {
+		t.Fatalf("version did not increase: v1=%d v2=%d", version1, version2)
+	}
+}
+
+func TestGetAllVersionsIterator(t *testing.T) {
+	path := openTestDB(t)
+
+	if err := setImpl(path, []byte("history-key"), []byte("v1")); err != nil {
+		t.Fatalf("setImpl v1 failed: %v", err)
+	}
+	if err := setImpl(path, []byte("history-key"), []byte("v2")); err != nil {
+		t.Fatalf("setImpl v2 failed: %v", err)
+	}
+
+	it, err := getAllVersionsImpl(path, []byte("history-key"))
+	if err != nil {
+		t.Fatalf("getAllVersionsImpl failed: %v", err)
+	}
+	defer iterCloseImpl(it)
+
+	count := 0
+	for iterValidImpl(it) {
+		count++
+		if err := iterNextImpl(it); err != nil {
+			t.Fatalf("iterNextImpl failed: %v", err)
+		}
+	}
+	if count < 2 {
+		t.Fatalf("iterated %d versions, want at least 2", count)
+	}
+}
+
+func TestGetAllVersionsIgnoresSiblingWithSharedPrefix(t *testing.T) {
+	path := openTestDB(t)
+
+	if err := setImpl(path, []byte("ab"), []byte("ab-v1")); err != nil {
+		t.Fatalf("setImpl ab v1 failed: %v", err)
+	}
+	if err := setImpl(path, []byte("ab"), []byte("ab-v2")); err != nil {
+		t.Fatalf("setImpl ab v2 failed: %v", err)
+	}
+	if err := setImpl(path, []byte("abc"), []byte("abc-v1")); err != nil {
+		t.Fatalf("setImpl abc failed: %v", err)
+	}
+
+	it, err := getAllVersionsImpl(path, []byte("ab"))
+	if err != nil {
+		t.Fatalf("getAllVersionsImpl failed: %v", err)
+	}
+	defer iterCloseImpl(it)
+
+	count := 0
+	for iterValidImpl(it) {
+		count++
+		key, err := iterKeyImpl(it)
+		if err != nil {
+			t.Fatalf("iterKeyImpl failed: %v", err)
+		}
+		if string(key) != "ab" {
+			t.Fatalf("iterated key %q, want only \"ab\"", key)
+		}
+		if err := iterNextImpl(it); err != nil {
+			t.Fatalf("iterNextImpl failed: %v", err)
+		}
+	}
+	if count != 2 {
+		t.Fatalf("iterated %d versions of \"ab\", want exactly 2", count)
+	}
+}
@@ -1,24 +1,228 @@
 package main
 
+/*
+#include <stdlib.h>
+*/
 import "C"
+
 import (
+	"sync"
+	"unsafe"
+
 	badger "github.com/dgraph-io/badger/v4"
 )
 
 // go build -o libbadgerdb.so -buildmode=c-shared badgerdb.go
 
-var dbMap map[string]*badger.DB
+const (
+	statusOK    = C.int(0)
+	statusError = C.int(-1)
+)
 
-//export OpenDB
-func OpenDB(path *C.char) *C.char {
-	options := badger.DefaultOptions(C.GoString(path))
+var (
+	dbMu  sync.RWMutex
+	dbMap map[string]*badger.DB
+
+	errMu   sync.Mutex
+	lastErr string
+)
+
+func init() {
+	dbMap = make(map[string]*badger.DB)
+}
+
+// setLastError records err (or clears it on nil) for later retrieval via
+// LastError and returns the matching status code.
+func setLastError(err error) C.int {
+	errMu.Lock()
+	if err != nil {
+		lastErr = err.Error()
+	} else {
+		lastErr = ""
+	}
+	errMu.Unlock()
+	if err != nil {
+		return statusError
+	}
+	return statusOK
+}
+
+// LastError returns the message from the most recently failed call, or ""
+// if the last call succeeded. The returned buffer is caller-owned; release
+// it with FreeBuffer.
+//
+//export LastError
+func LastError() *C.char {
+	errMu.Lock()
+	defer errMu.Unlock()
+	return C.CString(lastErr)
+}
+
+// FreeBuffer releases a buffer previously returned by Get (or any other
+// export documented as returning a caller-owned buffer).
+//
+//export FreeBuffer
+func FreeBuffer(buf *C.char) {
+	C.free(unsafe.Pointer(buf))
+}
+
+func getDB(path string) (*badger.DB, bool) {
+	dbMu.RLock()
+	defer dbMu.RUnlock()
+	db, ok := dbMap[path]
+	return db, ok
+}
+
+func openDBImpl(path string, options badger.Options) error {
+	dbMu.Lock()
+	defer dbMu.Unlock()
+
+	if _, ok := dbMap[path]; ok {
+		return nil
+	}
 
 	db, err := badger.Open(options)
 	if err != nil {
-		return C.CString(err.Error())
+		return err
+	}
+	dbMap[path] = db
+	return nil
+}
+
+//export OpenDB
+func OpenDB(path *C.char) C.int {
+	goPath := C.GoString(path)
+	return setLastError(openDBImpl(goPath, badger.DefaultOptions(goPath)))
+}
+
+func closeDBImpl(path string) error {
+	dbMu.Lock()
+	defer dbMu.Unlock()
+
+	db, ok := dbMap[path]
+	if !ok {
+		return badger.ErrDBClosed
+	}
+	if err := db.Close(); err != nil {
+		return err
+	}
+	delete(dbMap, path)
+	return nil
+}
+
+//export CloseDB
+func CloseDB(path *C.char) C.int {
+	return setLastError(closeDBImpl(C.GoString(path)))
+}
+
+func setImpl(path string, key, val []byte) error {
+	db, ok := getDB(path)
+	if !ok {
+		return badger.ErrDBClosed
+	}
+
+	return db.Update(func(txn *badger.Txn) error {
+		return txn.Set(key, val)
+	})
+}
+
+//export Set
+func Set(path *C.char, key *C.char, keyLen C.int, val *C.char, valLen C.int) C.int {
+	k := C.GoBytes(unsafe.Pointer(key), keyLen)
+	v := C.GoBytes(unsafe.Pointer(val), valLen)
+	return setLastError(setImpl(C.GoString(path), k, v))
+}
+
+func getImpl(path string, key []byte) ([]byte, error) {
+	db, ok := getDB(path)
+	if !ok {
+		return nil, badger.ErrDBClosed
+	}
+
+	var val []byte
+	err := db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(key)
+		if err != nil {
+			return err
+		}
+		val, err = item.ValueCopy(nil)
+		return err
+	})
+	return val, err
+}
+
+// Get looks up key and, on success, allocates *outVal with C.CBytes and sets
+// *outLen to its length. The caller owns the returned buffer and must
+// release it with FreeBuffer.
+//
+//export Get
+func Get(path *C.char, key *C.char, keyLen C.int, outVal **C.char, outLen *C.int) C.int {
+	k := C.GoBytes(unsafe.Pointer(key), keyLen)
+
+	val, err := getImpl(C.GoString(path), k)
+	if err != nil {
+		return setLastError(err)
+	}
+
+	*outVal = (*C.char)(C.CBytes(val))
+	*outLen = C.int(len(val))
+	return statusOK
+}
+
+func deleteImpl(path string, key []byte) error {
+	db, ok := getDB(path)
+	if !ok {
+		return badger.ErrDBClosed
+	}
+
+	return db.Update(func(txn *badger.Txn) error {
+		return txn.Delete(key)
+	})
+}
+
+//export Delete
+func Delete(path *C.char, key *C.char, keyLen C.int) C.int {
+	k := C.GoBytes(unsafe.Pointer(key), keyLen)
+	return setLastError(deleteImpl(C.GoString(path), k))
+}
+
+// existsImpl reports whether key is present. The bool return is only
+// meaningful when err is nil.
+func existsImpl(path string, key []byte) (bool, error) {
+	db, ok := getDB(path)
+	if !ok {
+		return false, badger.ErrDBClosed
+	}
+
+	err := db.View(func(txn *badger.Txn) error {
+		_, err := txn.Get(key)
+		return err
+	})
+	switch err {
+	case nil:
+		return true, nil
+	case badger.ErrKeyNotFound:
+		return false, nil
+	default:
+		return false, err
+	}
+}
+
+// Exists reports whether key is present: 1 if found, 0 if not found, or
+// statusError (with LastError set) if the lookup itself failed.
+//
+//export Exists
+func Exists(path *C.char, key *C.char, keyLen C.int) C.int {
+	k := C.GoBytes(unsafe.Pointer(key), keyLen)
+
+	found, err := existsImpl(C.GoString(path), k)
+	if err != nil {
+		return setLastError(err)
+	}
+	if found {
+		return C.int(1)
 	}
-	dbMap[C.GoString(path)] = db
-	return path
+	return C.int(0)
 }
 
 func main() {}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"testing"
+
+	badger "github.com/dgraph-io/badger/v4"
+)
+
+func openTestDB(t *testing.T) string {
+	t.Helper()
+	path := t.TempDir()
+	if err := openDBImpl(path, badger.DefaultOptions(path)); err != nil {
+		t.Fatalf("openDBImpl failed: %v", err)
+	}
+	t.Cleanup(func() { closeDBImpl(path) })
+	return path
+}
+
+func TestSetGetDelete(t *testing.T) {
+	path := openTestDB(t)
+
+	if err := setImpl(path, []byte("k"), []byte("v")); err != nil {
+		t.Fatalf("setImpl failed: %v", err)
+	}
+
+	val, err := getImpl(path, []byte("k"))
+	if err != nil {
+		t.Fatalf("getImpl failed: %v", err)
+	}
+	if string(val) != "v" {
+		t.Fatalf("getImpl = %q, want %q", val, "v")
+	}
+
+	if err := deleteImpl(path, []byte("k")); err != nil {
+		t.Fatalf("deleteImpl failed: %v", err)
+	}
+	if _, err := getImpl(path, []byte("k")); err != badger.ErrKeyNotFound {
+		t.Fatalf("getImpl after delete = %v, want ErrKeyNotFound", err)
+	}
+}
+
+func TestExists(t *testing.T) {
+	path := openTestDB(t)
+
+	found, err := existsImpl(path, []byte("missing"))
+	if err != nil {
+		t.Fatalf("existsImpl failed: %v", err)
+	}
+	if found {
+		t.Fatalf("existsImpl = true for a key never set")
+	}
+
+	if err := setImpl(path, []byte("present"), []byte("v")); err != nil {
+		t.Fatalf("setImpl failed: %v", err)
+	}
+
+	found, err = existsImpl(path, []byte("present"))
+	if err != nil {
+		t.Fatalf("existsImpl failed: %v", err)
+	}
+	if !found {
+		t.Fatalf("existsImpl = false for a key that was set")
+	}
+}
+
+func TestOpenDBIsIdempotent(t *testing.T) {
+	path := t.TempDir()
+	options := badger.DefaultOptions(path)
+
+	if err := openDBImpl(path, options); err != nil {
+		t.Fatalf("first openDBImpl failed: %v", err)
+	}
+	defer closeDBImpl(path)
+
+	if err := openDBImpl(path, options); err != nil {
+		t.Fatalf("second openDBImpl failed: %v", err)
+	}
+}
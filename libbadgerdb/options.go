@@ -0,0 +1,129 @@
+package main
+
+import "C"
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	badger "github.com/dgraph-io/badger/v4"
+	"github.com/dgraph-io/badger/v4/options"
+)
+
+// dbOptions mirrors the subset of badger.Options callers can tune without
+// recompiling the shared library. Fields left zero/empty fall back to
+// badger's own defaults.
+type dbOptions struct {
+	InMemory                      bool   `json:"InMemory"`
+	ValueDir                      string `json:"ValueDir"`
+	EncryptionKey                 string `json:"EncryptionKey"`
+	EncryptionKeyRotationDuration string `json:"EncryptionKeyRotationDuration"`
+	IndexCacheSize                int64  `json:"IndexCacheSize"`
+	BlockCacheSize                int64  `json:"BlockCacheSize"`
+	NumCompactors                 int    `json:"NumCompactors"`
+	Compression                   string `json:"Compression"`
+	ZSTDCompressionLevel          int    `json:"ZSTDCompressionLevel"`
+	SyncWrites                    bool   `json:"SyncWrites"`
+	Logger                        string `json:"Logger"`
+	NumVersionsToKeep             int    `json:"NumVersionsToKeep"`
+	// DetectConflicts is a pointer so an omitted field is distinguishable
+	// from an explicit false; badger.DefaultOptions defaults this to true.
+	DetectConflicts *bool `json:"DetectConflicts"`
+}
+
+func buildOptions(path string, opts dbOptions) (badger.Options, error) {
+	result := badger.DefaultOptions(path)
+
+	if opts.InMemory {
+		result = result.WithInMemory(true)
+	}
+	if opts.ValueDir != "" {
+		result = result.WithValueDir(opts.ValueDir)
+	}
+	if opts.EncryptionKey != "" {
+		key, err := hex.DecodeString(opts.EncryptionKey)
+		if err != nil {
+			return result, fmt.Errorf("EncryptionKey: %w", err)
+		}
+		result = result.WithEncryptionKey(key)
+	}
+	if opts.EncryptionKeyRotationDuration != "" {
+		d, err := time.ParseDuration(opts.EncryptionKeyRotationDuration)
+		if err != nil {
+			return result, fmt.Errorf("EncryptionKeyRotationDuration: %w", err)
+		}
+		result = result.WithEncryptionKeyRotationDuration(d)
+	}
+	if opts.IndexCacheSize != 0 {
+		result = result.WithIndexCacheSize(opts.IndexCacheSize)
+	}
+	if opts.BlockCacheSize != 0 {
+		result = result.WithBlockCacheSize(opts.BlockCacheSize)
+	}
+	if opts.NumCompactors != 0 {
+		result = result.WithNumCompactors(opts.NumCompactors)
+	}
+	if opts.Compression != "" {
+		switch opts.Compression {
+		case "none":
+			result = result.WithCompression(options.None)
+		case "snappy":
+			result = result.WithCompression(options.Snappy)
+		case "zstd":
+			result = result.WithCompression(options.ZSTD)
+		default:
+			return result, fmt.Errorf("Compression: unknown value %q", opts.Compression)
+		}
+	}
+	if opts.ZSTDCompressionLevel != 0 {
+		result = result.WithZSTDCompressionLevel(opts.ZSTDCompressionLevel)
+	}
+	result = result.WithSyncWrites(opts.SyncWrites)
+	if opts.DetectConflicts != nil {
+		result = result.WithDetectConflicts(*opts.DetectConflicts)
+	}
+	if opts.Logger != "" {
+		switch opts.Logger {
+		case "debug":
+			result = result.WithLoggingLevel(badger.DEBUG)
+		case "info":
+			result = result.WithLoggingLevel(badger.INFO)
+		case "warning":
+			result = result.WithLoggingLevel(badger.WARNING)
+		case "error":
+			result = result.WithLoggingLevel(badger.ERROR)
+		default:
+			return result, fmt.Errorf("Logger: unknown level %q", opts.Logger)
+		}
+	}
+	if opts.NumVersionsToKeep != 0 {
+		result = result.WithNumVersionsToKeep(opts.NumVersionsToKeep)
+	}
+
+	return result, nil
+}
+
+func openDBWithOptionsImpl(path, optsJSON string) error {
+	var parsed dbOptions
+	if err := json.Unmarshal([]byte(optsJSON), &parsed); err != nil {
+		return err
+	}
+
+	options, err := buildOptions(path, parsed)
+	if err != nil {
+		return err
+	}
+
+	return openDBImpl(path, options)
+}
+
+// OpenDBWithOptions is like OpenDB but parses optsJSON (see dbOptions) into
+// a badger.Options, allowing encryption, in-memory mode, and cache/compactor
+// tuning to be selected at call time instead of recompiling the library.
+//
+//export OpenDBWithOptions
+func OpenDBWithOptions(path *C.char, optsJSON *C.char) C.int {
+	return setLastError(openDBWithOptionsImpl(C.GoString(path), C.GoString(optsJSON)))
+}
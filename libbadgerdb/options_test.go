@@ -0,0 +1,30 @@
+package main
+
+import "testing"
+
+func TestBuildOptionsDetectConflictsDefault(t *testing.T) {
+	result, err := buildOptions(t.TempDir(), dbOptions{})
+	if err != nil {
+		t.Fatalf("buildOptions failed: %v", err)
+	}
+	if !result.DetectConflicts {
+		t.Fatalf("DetectConflicts = false when unset, want true (badger's default)")
+	}
+}
+
+func TestBuildOptionsDetectConflictsExplicitFalse(t *testing.T) {
+	disabled := false
+	result, err := buildOptions(t.TempDir(), dbOptions{DetectConflicts: &disabled})
+	if err != nil {
+		t.Fatalf("buildOptions failed: %v", err)
+	}
+	if result.DetectConflicts {
+		t.Fatalf("DetectConflicts = true, want false when explicitly disabled")
+	}
+}
+
+func TestBuildOptionsUnknownCompression(t *testing.T) {
+	if _, err := buildOptions(t.TempDir(), dbOptions{Compression: "lz4"}); err == nil {
+		t.Fatalf("expected an error for an unknown compression value")
+	}
+}
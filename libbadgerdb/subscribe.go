@@ -0,0 +1,208 @@
+package main
+
+/*
+#include <stdint.h>
+#include <stdlib.h>
+
+// subscribe_cb_t is invoked once per changed key. op is 0 for a set/update
+// and 1 for a delete. key/val point into memory owned by the runtime and
+// are only valid for the duration of the call; copy them if you need to
+// keep them.
+typedef void (*subscribe_cb_t)(int op, char* key, int keyLen, char* val, int valLen, void* userdata);
+
+static inline void call_subscribe_cb(subscribe_cb_t cb, int op, char* key, int keyLen, char* val, int valLen, void* userdata) {
+	cb(op, key, keyLen, val, valLen, userdata);
+}
+*/
+import "C"
+
+import (
+	"context"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+
+	badger "github.com/dgraph-io/badger/v4"
+	"github.com/dgraph-io/badger/v4/pb"
+)
+
+const (
+	subOpSet    = 0
+	subOpDelete = 1
+
+	// subscribeQueueDepth bounds the number of pending updates buffered for
+	// a single subscription. Once full, publishing blocks until the consumer
+	// drains it, applying backpressure instead of growing memory unboundedly
+	// if it is slow.
+	subscribeQueueDepth = 1024
+
+	// badgerReservedPrefix marks badger's own internal bookkeeping keys
+	// (e.g. "!badger!txn"). An empty/catch-all prefix match would otherwise
+	// also surface these to subscribers, who only expect their own data.
+	badgerReservedPrefix = "!badger!"
+)
+
+type subscribeUpdate struct {
+	op  int
+	key []byte
+	val []byte
+}
+
+type subscription struct {
+	cancel context.CancelFunc
+	queue  chan subscribeUpdate
+	done   chan struct{}
+}
+
+var (
+	subMu     sync.Mutex
+	subMap    map[uint64]*subscription
+	nextSubID uint64
+	subOnce   sync.Once
+)
+
+func initSubMap() {
+	subOnce.Do(func() {
+		subMap = make(map[uint64]*subscription)
+	})
+}
+
+func buildPrefixMatches(prefixes [][]byte) []pb.Match {
+	if len(prefixes) == 0 {
+		return []pb.Match{{Prefix: []byte{}}}
+	}
+	matches := make([]pb.Match, 0, len(prefixes))
+	for _, p := range prefixes {
+		matches = append(matches, pb.Match{Prefix: p})
+	}
+	return matches
+}
+
+// subscribeImpl watches for changes to keys under any of prefixes (all keys
+// if empty) and invokes notify once per changed key until the returned
+// handle is passed to unsubscribeImpl. When pinThread is true, the goroutine
+// that calls notify locks itself to a single OS thread for the lifetime of
+// the subscription, which callers crossing into C (like Subscribe) need
+// because C code may rely on thread-local state between calls.
+func subscribeImpl(path string, prefixes [][]byte, pinThread bool, notify func(op int, key, val []byte)) (uint64, error) {
+	initSubMap()
+
+	db, ok := getDB(path)
+	if !ok {
+		return 0, badger.ErrDBClosed
+	}
+
+	matches := buildPrefixMatches(prefixes)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	sub := &subscription{
+		cancel: cancel,
+		queue:  make(chan subscribeUpdate, subscribeQueueDepth),
+		done:   make(chan struct{}),
+	}
+
+	handle := atomic.AddUint64(&nextSubID, 1)
+	subMu.Lock()
+	subMap[handle] = sub
+	subMu.Unlock()
+
+	go func() {
+		if pinThread {
+			// Pinned because the callback repeatedly crosses into C code;
+			// unpinning between calls would otherwise let the Go runtime
+			// hop it across OS threads under the C caller's feet.
+			runtime.LockOSThread()
+			defer runtime.UnlockOSThread()
+		}
+		defer close(sub.done)
+
+		for update := range sub.queue {
+			notify(update.op, update.key, update.val)
+		}
+	}()
+
+	go func() {
+		err := db.Subscribe(ctx, func(kvs *pb.KVList) error {
+			for _, kv := range kvs.GetKv() {
+				if strings.HasPrefix(string(kv.GetKey()), badgerReservedPrefix) {
+					continue
+				}
+				op := subOpSet
+				if len(kv.GetValue()) == 0 {
+					op = subOpDelete
+				}
+				select {
+				case sub.queue <- subscribeUpdate{op: op, key: kv.GetKey(), val: kv.GetValue()}:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+			return nil
+		}, matches)
+		if err != nil && err != context.Canceled {
+			setLastError(err)
+		}
+		close(sub.queue)
+	}()
+
+	return handle, nil
+}
+
+func unsubscribeImpl(handle uint64) error {
+	subMu.Lock()
+	sub, ok := subMap[handle]
+	delete(subMap, handle)
+	subMu.Unlock()
+
+	if !ok {
+		return badger.ErrDiscardedTxn
+	}
+
+	sub.cancel()
+	<-sub.done
+	return nil
+}
+
+// Subscribe watches for changes to keys under any of the nPrefixes prefixes
+// and invokes cb on a dedicated OS thread for each one, until Unsubscribe is
+// called. It returns an opaque handle, or 0 on failure (see LastError).
+//
+//export Subscribe
+func Subscribe(path *C.char, prefixes **C.char, nPrefixes C.int, cb C.subscribe_cb_t, userdata unsafe.Pointer) C.uint64_t {
+	var goPrefixes [][]byte
+	if nPrefixes > 0 {
+		cPrefixes := (*[1 << 28]*C.char)(unsafe.Pointer(prefixes))[:nPrefixes:nPrefixes]
+		goPrefixes = make([][]byte, 0, len(cPrefixes))
+		for _, p := range cPrefixes {
+			goPrefixes = append(goPrefixes, []byte(C.GoString(p)))
+		}
+	}
+
+	notify := func(op int, key, val []byte) {
+		var keyPtr, valPtr *C.char
+		if len(key) > 0 {
+			keyPtr = (*C.char)(unsafe.Pointer(&key[0]))
+		}
+		if len(val) > 0 {
+			valPtr = (*C.char)(unsafe.Pointer(&val[0]))
+		}
+		C.call_subscribe_cb(cb, C.int(op), keyPtr, C.int(len(key)), valPtr, C.int(len(val)), userdata)
+	}
+
+	handle, err := subscribeImpl(C.GoString(path), goPrefixes, true, notify)
+	if err != nil {
+		setLastError(err)
+		return 0
+	}
+	return C.uint64_t(handle)
+}
+
+// Unsubscribe stops a subscription started with Subscribe and blocks until
+// its callback goroutine has drained and exited.
+//
+//export Unsubscribe
+func Unsubscribe(handle C.uint64_t) C.int {
+	return setLastError(unsubscribeImpl(uint64(handle)))
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSubscribeUnsubscribe(t *testing.T) {
+	path := openTestDB(t)
+
+	var mu sync.Mutex
+	var gotOp int
+	var gotKey, gotVal []byte
+	notified := make(chan struct{}, 1)
+
+	sub, err := subscribeImpl(path, nil, false, func(op int, key, val []byte) {
+		mu.Lock()
+		gotOp, gotKey, gotVal = op, key, val
+		mu.Unlock()
+		select {
+		case notified <- struct{}{}:
+		default:
+		}
+	})
+	if err != nil {
+		t.Fatalf("subscribeImpl failed: %v", err)
+	}
+	defer unsubscribeImpl(sub)
+
+	if err := setImpl(path, []byte("sub-key"), []byte("sub-val")); err != nil {
+		t.Fatalf("setImpl failed: %v", err)
+	}
+
+	select {
+	case <-notified:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("subscribe callback was never invoked")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if gotOp != subOpSet {
+		t.Fatalf("op = %d, want %d (set)", gotOp, subOpSet)
+	}
+	if string(gotKey) != "sub-key" {
+		t.Fatalf("key = %q, want %q", gotKey, "sub-key")
+	}
+	if string(gotVal) != "sub-val" {
+		t.Fatalf("val = %q, want %q", gotVal, "sub-val")
+	}
+}
+
+func TestBuildPrefixMatchesDefaultsToEmptyPrefix(t *testing.T) {
+	matches := buildPrefixMatches(nil)
+	if len(matches) != 1 || len(matches[0].Prefix) != 0 {
+		t.Fatalf("buildPrefixMatches(nil) = %+v, want a single empty-prefix match", matches)
+	}
+}
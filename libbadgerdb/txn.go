@@ -0,0 +1,365 @@
+package main
+
+/*
+#include <stdint.h>
+*/
+import "C"
+
+import (
+	"bytes"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+
+	badger "github.com/dgraph-io/badger/v4"
+)
+
+var (
+	txnMu      sync.RWMutex
+	txnMap     map[uint64]*badger.Txn
+	nextTxnID  uint64
+	txnInitOne sync.Once
+
+	iterMu      sync.RWMutex
+	iterMap     map[uint64]*badgerIterator
+	nextIterID  uint64
+	iterInitOne sync.Once
+)
+
+type badgerIterator struct {
+	txn      uint64
+	it       *badger.Iterator
+	ownsTxn  bool   // true if IterClose should also discard txn (e.g. GetAllVersions' private read txn)
+	exactKey []byte // non-nil restricts Valid to items whose key equals this exactly, e.g. GetAllVersions over a Prefix scan
+}
+
+func initTxnMaps() {
+	txnInitOne.Do(func() {
+		txnMap = make(map[uint64]*badger.Txn)
+	})
+	iterInitOne.Do(func() {
+		iterMap = make(map[uint64]*badgerIterator)
+	})
+}
+
+func getTxn(handle uint64) (*badger.Txn, bool) {
+	txnMu.RLock()
+	defer txnMu.RUnlock()
+	txn, ok := txnMap[handle]
+	return txn, ok
+}
+
+func getIter(handle uint64) (*badgerIterator, bool) {
+	iterMu.RLock()
+	defer iterMu.RUnlock()
+	it, ok := iterMap[handle]
+	return it, ok
+}
+
+func beginTxnImpl(path string, update bool) (uint64, error) {
+	initTxnMaps()
+
+	db, ok := getDB(path)
+	if !ok {
+		return 0, badger.ErrDBClosed
+	}
+
+	txn := db.NewTransaction(update)
+
+	handle := atomic.AddUint64(&nextTxnID, 1)
+	txnMu.Lock()
+	txnMap[handle] = txn
+	txnMu.Unlock()
+
+	return handle, nil
+}
+
+// BeginTxn starts a new transaction against the DB opened at path and
+// returns an opaque handle to it, or 0 on failure (see LastError).
+//
+//export BeginTxn
+func BeginTxn(path *C.char, update C.int) C.uint64_t {
+	handle, err := beginTxnImpl(C.GoString(path), update != 0)
+	if err != nil {
+		setLastError(err)
+		return 0
+	}
+	return C.uint64_t(handle)
+}
+
+func txnSetImpl(handle uint64, key, val []byte) error {
+	txn, ok := getTxn(handle)
+	if !ok {
+		return badger.ErrDiscardedTxn
+	}
+	return txn.Set(key, val)
+}
+
+//export TxnSet
+func TxnSet(handle C.uint64_t, key *C.char, keyLen C.int, val *C.char, valLen C.int) C.int {
+	k := C.GoBytes(unsafe.Pointer(key), keyLen)
+	v := C.GoBytes(unsafe.Pointer(val), valLen)
+	return setLastError(txnSetImpl(uint64(handle), k, v))
+}
+
+func txnGetImpl(handle uint64, key []byte) ([]byte, error) {
+	txn, ok := getTxn(handle)
+	if !ok {
+		return nil, badger.ErrDiscardedTxn
+	}
+
+	item, err := txn.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	return item.ValueCopy(nil)
+}
+
+// TxnGet mirrors Get but reads through the transaction identified by handle.
+// On success the caller owns *outVal and must release it with FreeBuffer.
+//
+//export TxnGet
+func TxnGet(handle C.uint64_t, key *C.char, keyLen C.int, outVal **C.char, outLen *C.int) C.int {
+	k := C.GoBytes(unsafe.Pointer(key), keyLen)
+
+	val, err := txnGetImpl(uint64(handle), k)
+	if err != nil {
+		return setLastError(err)
+	}
+
+	*outVal = (*C.char)(C.CBytes(val))
+	*outLen = C.int(len(val))
+	return statusOK
+}
+
+func txnDeleteImpl(handle uint64, key []byte) error {
+	txn, ok := getTxn(handle)
+	if !ok {
+		return badger.ErrDiscardedTxn
+	}
+	return txn.Delete(key)
+}
+
+//export TxnDelete
+func TxnDelete(handle C.uint64_t, key *C.char, keyLen C.int) C.int {
+	k := C.GoBytes(unsafe.Pointer(key), keyLen)
+	return setLastError(txnDeleteImpl(uint64(handle), k))
+}
+
+func txnCommitImpl(handle uint64) error {
+	txnMu.Lock()
+	txn, ok := txnMap[handle]
+	delete(txnMap, handle)
+	txnMu.Unlock()
+
+	if !ok {
+		return badger.ErrDiscardedTxn
+	}
+	return txn.Commit()
+}
+
+// TxnCommit commits the transaction and releases its handle regardless of
+// outcome.
+//
+//export TxnCommit
+func TxnCommit(handle C.uint64_t) C.int {
+	return setLastError(txnCommitImpl(uint64(handle)))
+}
+
+func txnDiscardImpl(handle uint64) error {
+	txnMu.Lock()
+	txn, ok := txnMap[handle]
+	delete(txnMap, handle)
+	txnMu.Unlock()
+
+	if !ok {
+		return badger.ErrDiscardedTxn
+	}
+	txn.Discard()
+	return nil
+}
+
+// TxnDiscard discards the transaction and releases its handle. Any
+// iterators opened against it are no longer valid and must be closed by
+// the caller.
+//
+//export TxnDiscard
+func TxnDiscard(handle C.uint64_t) C.int {
+	return setLastError(txnDiscardImpl(uint64(handle)))
+}
+
+func newIteratorImpl(txnHandle uint64, prefix []byte, reverse bool) (uint64, error) {
+	initTxnMaps()
+
+	txn, ok := getTxn(txnHandle)
+	if !ok {
+		return 0, badger.ErrDiscardedTxn
+	}
+
+	opts := badger.DefaultIteratorOptions
+	opts.Reverse = reverse
+	if len(prefix) > 0 {
+		opts.Prefix = prefix
+	}
+
+	it := txn.NewIterator(opts)
+
+	handle := atomic.AddUint64(&nextIterID, 1)
+	iterMu.Lock()
+	iterMap[handle] = &badgerIterator{txn: txnHandle, it: it}
+	iterMu.Unlock()
+
+	return handle, nil
+}
+
+// NewIterator opens an iterator over txn scoped to the given key prefix
+// (pass prefixLen 0 for no prefix) and returns an opaque handle, or 0 on
+// failure. The iterator is not positioned; call IterSeek before reading.
+//
+//export NewIterator
+func NewIterator(txnHandle C.uint64_t, prefix *C.char, prefixLen C.int, reverse C.int) C.uint64_t {
+	var p []byte
+	if prefixLen > 0 {
+		p = C.GoBytes(unsafe.Pointer(prefix), prefixLen)
+	}
+
+	handle, err := newIteratorImpl(uint64(txnHandle), p, reverse != 0)
+	if err != nil {
+		setLastError(err)
+		return 0
+	}
+	return C.uint64_t(handle)
+}
+
+func iterSeekImpl(handle uint64, key []byte) error {
+	bi, ok := getIter(handle)
+	if !ok {
+		return badger.ErrDiscardedTxn
+	}
+	if len(key) > 0 {
+		bi.it.Seek(key)
+	} else {
+		bi.it.Rewind()
+	}
+	return nil
+}
+
+//export IterSeek
+func IterSeek(handle C.uint64_t, key *C.char, keyLen C.int) C.int {
+	var k []byte
+	if keyLen > 0 {
+		k = C.GoBytes(unsafe.Pointer(key), keyLen)
+	}
+	return setLastError(iterSeekImpl(uint64(handle), k))
+}
+
+func iterValidImpl(handle uint64) bool {
+	bi, ok := getIter(handle)
+	if !ok {
+		return false
+	}
+	if !bi.it.Valid() {
+		return false
+	}
+	if bi.exactKey != nil && !bytes.Equal(bi.it.Item().Key(), bi.exactKey) {
+		return false
+	}
+	return true
+}
+
+// IterValid reports whether the iterator currently points at a valid item:
+// 1 if so, 0 otherwise.
+//
+//export IterValid
+func IterValid(handle C.uint64_t) C.int {
+	if iterValidImpl(uint64(handle)) {
+		return C.int(1)
+	}
+	return C.int(0)
+}
+
+func iterNextImpl(handle uint64) error {
+	bi, ok := getIter(handle)
+	if !ok {
+		return badger.ErrDiscardedTxn
+	}
+	bi.it.Next()
+	return nil
+}
+
+//export IterNext
+func IterNext(handle C.uint64_t) C.int {
+	return setLastError(iterNextImpl(uint64(handle)))
+}
+
+func iterKeyImpl(handle uint64) ([]byte, error) {
+	bi, ok := getIter(handle)
+	if !ok {
+		return nil, badger.ErrDiscardedTxn
+	}
+	return bi.it.Item().KeyCopy(nil), nil
+}
+
+// IterKey returns the current item's key in a caller-owned buffer that
+// must be released with FreeBuffer.
+//
+//export IterKey
+func IterKey(handle C.uint64_t, outKey **C.char, outLen *C.int) C.int {
+	key, err := iterKeyImpl(uint64(handle))
+	if err != nil {
+		return setLastError(err)
+	}
+	*outKey = (*C.char)(C.CBytes(key))
+	*outLen = C.int(len(key))
+	return statusOK
+}
+
+func iterValueImpl(handle uint64) ([]byte, error) {
+	bi, ok := getIter(handle)
+	if !ok {
+		return nil, badger.ErrDiscardedTxn
+	}
+	return bi.it.Item().ValueCopy(nil)
+}
+
+// IterValue returns the current item's value in a caller-owned buffer that
+// must be released with FreeBuffer.
+//
+//export IterValue
+func IterValue(handle C.uint64_t, outVal **C.char, outLen *C.int) C.int {
+	val, err := iterValueImpl(uint64(handle))
+	if err != nil {
+		return setLastError(err)
+	}
+	*outVal = (*C.char)(C.CBytes(val))
+	*outLen = C.int(len(val))
+	return statusOK
+}
+
+func iterCloseImpl(handle uint64) error {
+	iterMu.Lock()
+	bi, ok := iterMap[handle]
+	delete(iterMap, handle)
+	iterMu.Unlock()
+
+	if !ok {
+		return badger.ErrDiscardedTxn
+	}
+	bi.it.Close()
+
+	if bi.ownsTxn {
+		txnMu.Lock()
+		if txn, ok := txnMap[bi.txn]; ok {
+			txn.Discard()
+			delete(txnMap, bi.txn)
+		}
+		txnMu.Unlock()
+	}
+
+	return nil
+}
+
+//export IterClose
+func IterClose(handle C.uint64_t) C.int {
+	return setLastError(iterCloseImpl(uint64(handle)))
+}
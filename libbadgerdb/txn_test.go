@@ -0,0 +1,154 @@
+package main
+
+import "testing"
+
+func TestTxnCommitDiscard(t *testing.T) {
+	path := openTestDB(t)
+
+	txn, err := beginTxnImpl(path, true)
+	if err != nil {
+		t.Fatalf("beginTxnImpl failed: %v", err)
+	}
+	if err := txnSetImpl(txn, []byte("txn-key"), []byte("txn-val")); err != nil {
+		t.Fatalf("txnSetImpl failed: %v", err)
+	}
+	if err := txnCommitImpl(txn); err != nil {
+		t.Fatalf("txnCommitImpl failed: %v", err)
+	}
+
+	// The handle must not be reusable after commit.
+	if err := txnSetImpl(txn, []byte("txn-key"), []byte("txn-val")); err == nil {
+		t.Fatalf("txnSetImpl succeeded on a committed handle")
+	}
+
+	readTxn, err := beginTxnImpl(path, false)
+	if err != nil {
+		t.Fatalf("beginTxnImpl (read) failed: %v", err)
+	}
+	defer txnDiscardImpl(readTxn)
+
+	val, err := txnGetImpl(readTxn, []byte("txn-key"))
+	if err != nil {
+		t.Fatalf("txnGetImpl failed: %v", err)
+	}
+	if string(val) != "txn-val" {
+		t.Fatalf("txnGetImpl = %q, want %q", val, "txn-val")
+	}
+}
+
+func TestTxnDeleteDiscard(t *testing.T) {
+	path := openTestDB(t)
+
+	txn, err := beginTxnImpl(path, true)
+	if err != nil {
+		t.Fatalf("beginTxnImpl failed: %v", err)
+	}
+	if err := txnSetImpl(txn, []byte("k"), []byte("v")); err != nil {
+		t.Fatalf("txnSetImpl failed: %v", err)
+	}
+	if err := txnDeleteImpl(txn, []byte("k")); err != nil {
+		t.Fatalf("txnDeleteImpl failed: %v", err)
+	}
+	if err := txnDiscardImpl(txn); err != nil {
+		t.Fatalf("txnDiscardImpl failed: %v", err)
+	}
+
+	// The handle must not be reusable after discard.
+	if err := txnDiscardImpl(txn); err == nil {
+		t.Fatalf("txnDiscardImpl succeeded twice on the same handle")
+	}
+}
+
+func TestIteratorSeekNext(t *testing.T) {
+	path := openTestDB(t)
+
+	txn, err := beginTxnImpl(path, true)
+	if err != nil {
+		t.Fatalf("beginTxnImpl failed: %v", err)
+	}
+	for _, k := range []string{"a", "b", "c"} {
+		if err := txnSetImpl(txn, []byte(k), []byte(k)); err != nil {
+			t.Fatalf("txnSetImpl(%s) failed: %v", k, err)
+		}
+	}
+	if err := txnCommitImpl(txn); err != nil {
+		t.Fatalf("txnCommitImpl failed: %v", err)
+	}
+
+	readTxn, err := beginTxnImpl(path, false)
+	if err != nil {
+		t.Fatalf("beginTxnImpl (read) failed: %v", err)
+	}
+	defer txnDiscardImpl(readTxn)
+
+	it, err := newIteratorImpl(readTxn, nil, false)
+	if err != nil {
+		t.Fatalf("newIteratorImpl failed: %v", err)
+	}
+	defer iterCloseImpl(it)
+
+	if err := iterSeekImpl(it, nil); err != nil {
+		t.Fatalf("iterSeekImpl failed: %v", err)
+	}
+
+	count := 0
+	for iterValidImpl(it) {
+		count++
+		if err := iterNextImpl(it); err != nil {
+			t.Fatalf("iterNextImpl failed: %v", err)
+		}
+	}
+	if count != 3 {
+		t.Fatalf("iterated %d keys, want 3", count)
+	}
+}
+
+func TestIteratorKeyValue(t *testing.T) {
+	path := openTestDB(t)
+
+	txn, err := beginTxnImpl(path, true)
+	if err != nil {
+		t.Fatalf("beginTxnImpl failed: %v", err)
+	}
+	if err := txnSetImpl(txn, []byte("only-key"), []byte("only-val")); err != nil {
+		t.Fatalf("txnSetImpl failed: %v", err)
+	}
+	if err := txnCommitImpl(txn); err != nil {
+		t.Fatalf("txnCommitImpl failed: %v", err)
+	}
+
+	readTxn, err := beginTxnImpl(path, false)
+	if err != nil {
+		t.Fatalf("beginTxnImpl (read) failed: %v", err)
+	}
+	defer txnDiscardImpl(readTxn)
+
+	it, err := newIteratorImpl(readTxn, nil, false)
+	if err != nil {
+		t.Fatalf("newIteratorImpl failed: %v", err)
+	}
+	defer iterCloseImpl(it)
+
+	if err := iterSeekImpl(it, nil); err != nil {
+		t.Fatalf("iterSeekImpl failed: %v", err)
+	}
+	if !iterValidImpl(it) {
+		t.Fatalf("iterator not valid after seek")
+	}
+
+	key, err := iterKeyImpl(it)
+	if err != nil {
+		t.Fatalf("iterKeyImpl failed: %v", err)
+	}
+	if string(key) != "only-key" {
+		t.Fatalf("iterKeyImpl = %q, want %q", key, "only-key")
+	}
+
+	val, err := iterValueImpl(it)
+	if err != nil {
+		t.Fatalf("iterValueImpl failed: %v", err)
+	}
+	if string(val) != "only-val" {
+		t.Fatalf("iterValueImpl = %q, want %q", val, "only-val")
+	}
+}